@@ -0,0 +1,70 @@
+package sendgrid
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// APIError represents a non-2xx response from the SendGrid API. It
+// preserves the HTTP status, the request id SendGrid attached to the
+// response, and any per-field errors carried in the response's JSON error
+// payload, while still matching the package's existing sentinel errors
+// (ErrBadRequest, ErrForbidden, ...) via errors.Is.
+type APIError struct {
+	Status    int
+	RequestId string
+	Errors    []Error
+	sentinel  error
+}
+
+func (e *APIError) Error() string {
+	if len(e.Errors) == 0 {
+		return e.sentinel.Error()
+	}
+	var s strings.Builder
+	s.WriteString(e.sentinel.Error())
+	s.WriteString(": ")
+	for i, fe := range e.Errors {
+		if i > 0 {
+			s.WriteString("; ")
+		}
+		s.WriteString(fe.Error())
+	}
+	return s.String()
+}
+
+// Unwrap exposes the underlying sentinel error so existing callers using
+// errors.Is(err, ErrBadRequest) and friends keep working unchanged.
+func (e *APIError) Unwrap() error {
+	return e.sentinel
+}
+
+// errorBody is the shape of SendGrid's JSON error payload
+type errorBody struct {
+	Errors []Error `json:"errors"`
+}
+
+// newAPIError builds an APIError from a non-2xx response, parsing
+// SendGrid's JSON error payload when the response body carries one
+func newAPIError(rsp *http.Response, data []byte, sentinel error) *APIError {
+	err := &APIError{
+		Status:    rsp.StatusCode,
+		RequestId: firstNonEmpty(rsp.Header.Get("X-Message-Id"), rsp.Header.Get("X-Request-Id")),
+		sentinel:  sentinel,
+	}
+	var body errorBody
+	if json.Unmarshal(data, &body) == nil {
+		err.Errors = body.Errors
+	}
+	return err
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package sendgrid
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNewAPIErrorParsesErrorBody(t *testing.T) {
+	rsp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"X-Message-Id": []string{"msg-1"}},
+	}
+	body := []byte(`{"errors":[{"message":"email is required","field":"personalizations.0.to"}]}`)
+
+	err := newAPIError(rsp, body, ErrBadRequest)
+	if err.Status != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, err.Status)
+	}
+	if err.RequestId != "msg-1" {
+		t.Errorf("expected request id %q, got %q", "msg-1", err.RequestId)
+	}
+	if len(err.Errors) != 1 || err.Errors[0].Message != "email is required" {
+		t.Fatalf("expected one parsed field error, got %+v", err.Errors)
+	}
+}
+
+func TestNewAPIErrorTolerantOfUnparsableBody(t *testing.T) {
+	rsp := &http.Response{StatusCode: http.StatusInternalServerError, Header: http.Header{}}
+	err := newAPIError(rsp, []byte("not json"), ErrServiceError)
+	if len(err.Errors) != 0 {
+		t.Fatalf("expected no parsed errors for an unparsable body, got %+v", err.Errors)
+	}
+	if err.Error() != ErrServiceError.Error() {
+		t.Fatalf("expected message to fall back to the sentinel, got %q", err.Error())
+	}
+}
+
+func TestAPIErrorUnwrapMatchesSentinelViaErrorsIs(t *testing.T) {
+	rsp := &http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}}
+	err := newAPIError(rsp, nil, ErrForbidden)
+
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatal("expected errors.Is to match the wrapped sentinel")
+	}
+	if errors.Is(err, ErrBadRequest) {
+		t.Fatal("expected errors.Is not to match an unrelated sentinel")
+	}
+}
+
+func TestAPIErrorMessageIncludesFieldErrors(t *testing.T) {
+	rsp := &http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{}}
+	body := []byte(`{"errors":[{"message":"bad field"},{"message":"bad field 2"}]}`)
+	err := newAPIError(rsp, body, ErrBadRequest)
+
+	want := ErrBadRequest.Error() + ": bad field; bad field 2"
+	if err.Error() != want {
+		t.Fatalf("expected message %q, got %q", want, err.Error())
+	}
+}
+
+func TestFirstNonEmpty(t *testing.T) {
+	cases := []struct {
+		vals []string
+		want string
+	}{
+		{[]string{"", "", "c"}, "c"},
+		{[]string{"a", "b"}, "a"},
+		{[]string{"", ""}, ""},
+		{nil, ""},
+	}
+	for _, c := range cases {
+		if got := firstNonEmpty(c.vals...); got != c.want {
+			t.Errorf("firstNonEmpty(%v) = %q, want %q", c.vals, got, c.want)
+		}
+	}
+}
+
+func TestNewAPIErrorPrefersMessageIdOverRequestId(t *testing.T) {
+	rsp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header: http.Header{
+			"X-Message-Id": []string{"msg-1"},
+			"X-Request-Id": []string{"req-1"},
+		},
+	}
+	err := newAPIError(rsp, nil, ErrBadRequest)
+	if err.RequestId != "msg-1" {
+		t.Fatalf("expected X-Message-Id to take precedence, got %q", err.RequestId)
+	}
+}
+
+func TestNewAPIErrorFallsBackToRequestId(t *testing.T) {
+	rsp := &http.Response{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"X-Request-Id": []string{"req-1"}},
+	}
+	err := newAPIError(rsp, nil, ErrBadRequest)
+	if err.RequestId != "req-1" {
+		t.Fatalf("expected fallback to X-Request-Id, got %q", err.RequestId)
+	}
+}
@@ -2,6 +2,7 @@ package sendgrid
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -10,19 +11,23 @@ import (
 	"time"
 
 	"github.com/bww/go-util/v1/debug"
-	"github.com/bww/go-util/v1/text"
 	"github.com/bww/go-util/v1/urls"
+	"github.com/google/uuid"
 )
 
 const defaultEndpoint = "https://api.sendgrid.com/v3"
 
 // A Sendgrid client
 type Client interface {
-	StoreContacts(contacts []*Contact, lists []string) error
+	StoreContacts(contacts []*Contact, lists []string, opts ...RequestOption) error
+	StoreContactsAsync(contacts []*Contact, lists []string, opts ...RequestOption) (JobID, error)
+	ContactImportStatus(id JobID) (*ImportStatus, error)
+	WaitForImport(ctx context.Context, id JobID, poll time.Duration) (*ImportStatus, error)
+	StreamContacts(ctx context.Context, contacts <-chan *Contact, lists []string, batchSize, concurrency int) <-chan StreamResult
 	FetchContact(id string) (*Contact, error)
 	FetchContactByEmail(email string) (*Contact, error)
 	FetchContactWithParams(params url.Values) (*Contact, error)
-	SendEmail(email *Email) error
+	SendEmail(email *Email, opts ...RequestOption) error
 }
 
 type client struct {
@@ -31,7 +36,10 @@ type client struct {
 	apikey          string
 	overrideAddress string
 	defaultSender   Address
-	verbose         bool
+	logger          Logger
+	retryMax        int
+	retryBase       time.Duration
+	retryCap        time.Duration
 }
 
 // Create a client
@@ -43,16 +51,30 @@ func New(apikey string, opts ...Option) (Client, error) {
 	for _, o := range opts {
 		conf = o(conf)
 	}
+	hc := conf.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: time.Second * 30}
+	}
 	return &client{
-		client:          &http.Client{Timeout: time.Second * 30},
+		client:          hc,
 		apikey:          apikey,
 		base:            conf.Endpoint,
 		overrideAddress: conf.OverrideAddress,
 		defaultSender:   conf.DefaultSender,
-		verbose:         conf.Verbose,
+		logger:          effectiveLogger(conf),
+		retryMax:        conf.RetryMax,
+		retryBase:       conf.RetryBase,
+		retryCap:        conf.RetryCap,
 	}, nil
 }
 
+// withAutoIdempotencyKey seeds a per-call Idempotency-Key so that a retried
+// SendEmail or StoreContacts call is safe to replay; an explicit
+// WithIdempotencyKey passed by the caller still takes precedence.
+func withAutoIdempotencyKey() RequestOption {
+	return WithIdempotencyKey(uuid.NewString())
+}
+
 // Import contacts request
 type storeContactsRequest struct {
 	Lists    []string   `json:"list_ids"`
@@ -60,7 +82,7 @@ type storeContactsRequest struct {
 }
 
 // Create or update a contact
-func (c client) StoreContacts(contacts []*Contact, lists []string) error {
+func (c client) StoreContacts(contacts []*Contact, lists []string, opts ...RequestOption) error {
 	entity := storeContactsRequest{
 		Contacts: contacts,
 		Lists:    lists,
@@ -76,7 +98,7 @@ func (c client) StoreContacts(contacts []*Contact, lists []string) error {
 		return err
 	}
 
-	_, data, err = c.Send(req)
+	_, data, err = c.Send(req, append([]RequestOption{withAutoIdempotencyKey()}, opts...)...)
 	if err != nil {
 		return err
 	}
@@ -126,7 +148,7 @@ func (c client) FetchContactByEmail(email string) (*Contact, error) {
 }
 
 // Send an email
-func (c client) SendEmail(email *Email) error {
+func (c client) SendEmail(email *Email, opts ...RequestOption) error {
 	data, err := json.Marshal(prepareEmail(email, c.defaultSender, c.overrideAddress))
 	if err != nil {
 		return err
@@ -137,7 +159,7 @@ func (c client) SendEmail(email *Email) error {
 		return err
 	}
 
-	_, _, err = c.Send(req)
+	_, _, err = c.Send(req, append([]RequestOption{withAutoIdempotencyKey()}, opts...)...)
 	if err != nil {
 		return err
 	}
@@ -146,60 +168,95 @@ func (c client) SendEmail(email *Email) error {
 }
 
 // Perform an authenticated request; the parameter request will be
-// mutated to include authentication and content type
-func (c client) Send(req *http.Request) (*http.Response, []byte, error) {
+// mutated to include authentication and content type. Idempotent verbs
+// (GET/PUT), and POSTs carrying an Idempotency-Key, are retried on 429 and
+// 5xx responses per the client's retry policy, which opts and the request
+// itself may override.
+func (c client) Send(req *http.Request, opts ...RequestOption) (*http.Response, []byte, error) {
+	rc := requestConfig{
+		retryMax:  c.retryMax,
+		retryBase: c.retryBase,
+		retryCap:  c.retryCap,
+	}
+	for _, o := range opts {
+		rc = o(rc)
+	}
+
 	if c.apikey != "" {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apikey))
 	}
 	if req.Body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	if rc.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", rc.idempotencyKey)
+	}
+
+	httpClient := c.client
+	if rc.timeout > 0 {
+		dup := *c.client
+		dup.Timeout = rc.timeout
+		httpClient = &dup
+	}
+
+	retryable := isIdempotentMethod(req.Method) || (req.Method == http.MethodPost && rc.idempotencyKey != "")
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, nil, err
+			}
+			req.Body = body
+		}
 
-	if c.verbose {
-		fmt.Println("sendgrid:", req.Method, req.URL)
 		if req.Body != nil {
 			data, err := ioutil.ReadAll(req.Body)
 			if err != nil {
 				return nil, nil, err
 			}
 			req.Body = ioutil.NopCloser(bytes.NewBuffer(data))
-			fmt.Println(text.Indent(string(data), " > "))
-			fmt.Println(" * ")
+			c.logger.Debug("sendgrid: request", "method", req.Method, "url", req.URL.String(), "attempt", attempt, "body", string(data))
+		} else {
+			c.logger.Debug("sendgrid: request", "method", req.Method, "url", req.URL.String(), "attempt", attempt)
 		}
-	}
 
-	rsp, err := c.client.Do(req)
-	if err != nil {
-		return nil, nil, err
-	} else {
-		defer rsp.Body.Close()
-	}
+		rsp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
 
-	data, err := ioutil.ReadAll(rsp.Body)
-	if err != nil {
-		return nil, nil, err
-	}
+		data, err := ioutil.ReadAll(rsp.Body)
+		rsp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
 
-	if c.verbose {
-		fmt.Println(text.Indent(string(data), " < "))
-		fmt.Println()
-	}
+		c.logger.Debug("sendgrid: response", "status", rsp.StatusCode, "body", string(data))
 
-	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
-		return rsp, data, nil
-	}
+		if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+			return rsp, data, nil
+		}
 
-	switch rsp.StatusCode {
-	case http.StatusForbidden:
-		return nil, nil, ErrForbidden
-	case http.StatusUnauthorized:
-		return nil, nil, ErrUnauthorized
-	case http.StatusBadRequest:
-		return nil, nil, ErrBadRequest
-	case http.StatusInternalServerError:
-		return nil, nil, ErrServiceError
-	default:
-		return nil, nil, fmt.Errorf("Unexpected status code: %v", rsp.Status)
+		if retryable && attempt < rc.retryMax && isRetryableStatus(rsp.StatusCode) {
+			time.Sleep(backoff(rsp, attempt, rc.retryBase, rc.retryCap))
+			continue
+		}
+
+		var sentinel error
+		switch rsp.StatusCode {
+		case http.StatusForbidden:
+			sentinel = ErrForbidden
+		case http.StatusUnauthorized:
+			sentinel = ErrUnauthorized
+		case http.StatusBadRequest:
+			sentinel = ErrBadRequest
+		case http.StatusInternalServerError:
+			sentinel = ErrServiceError
+		default:
+			sentinel = fmt.Errorf("Unexpected status code: %v", rsp.Status)
+		}
+		return nil, nil, newAPIError(rsp, data, sentinel)
 	}
 }
 
@@ -0,0 +1,127 @@
+package sendgrid
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendRetriesOnRetryableStatus(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int
+	var keys []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		mu.Unlock()
+
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, err := New("apikey", Endpoint(srv.URL), WithRetry(5, time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req, err := http.NewRequest("PUT", srv.URL, bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	rsp, _, err := c.(*client).Send(req, WithIdempotencyKey("fixed-key"))
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if rsp.StatusCode != http.StatusOK {
+		t.Fatalf("expected final response to be 200, got %d", rsp.StatusCode)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts (2 retries), got %d", attempts)
+	}
+	for i, k := range keys {
+		if k != "fixed-key" {
+			t.Fatalf("attempt %d: expected Idempotency-Key %q on every attempt, got %q", i, "fixed-key", k)
+		}
+	}
+}
+
+func TestSendStopsRetryingAtRetryMax(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	c, err := New("apikey", Endpoint(srv.URL), WithRetry(2, time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	_, _, err = c.(*client).Send(req)
+	if err == nil {
+		t.Fatal("expected an error once retries are exhausted")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 3 {
+		t.Fatalf("expected retryMax=2 to allow 3 total attempts, got %d", attempts)
+	}
+}
+
+func TestSendDoesNotRetryNonIdempotentPostWithoutIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var attempts int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	c, err := New("apikey", Endpoint(srv.URL), WithRetry(5, time.Millisecond, 10*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req, err := http.NewRequest("POST", srv.URL, bytes.NewReader([]byte(`{}`)))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	_, _, err = c.(*client).Send(req)
+	if err == nil {
+		t.Fatal("expected an error since the request was never retried")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 1 {
+		t.Fatalf("expected exactly 1 attempt for a non-idempotent POST without an Idempotency-Key, got %d", attempts)
+	}
+}
@@ -0,0 +1,213 @@
+package sendgrid
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bww/go-util/v1/urls"
+)
+
+// SendGrid's documented limit on the number of contacts accepted by a
+// single PUT to /marketing/contacts
+const maxContactBatchSize = 30000
+
+// The identifier of an asynchronous contacts import job
+type JobID string
+
+// The result of an asynchronous contacts import job, as reported by
+// /marketing/contacts/imports/{id}
+type ImportStatus struct {
+	Id      JobID         `json:"id"`
+	Status  string        `json:"status"`
+	Results ImportResults `json:"results"`
+}
+
+// Per-outcome counts for a completed or in-progress import job
+type ImportResults struct {
+	Requested int     `json:"requested_count"`
+	Created   int     `json:"created_count"`
+	Updated   int     `json:"updated_count"`
+	Deleted   int     `json:"deleted_count"`
+	Errored   int     `json:"errored_count"`
+	Errors    []Error `json:"errors,omitempty"`
+}
+
+func (s *ImportStatus) isTerminal() bool {
+	switch s.Status {
+	case "completed", "errored", "failed":
+		return true
+	default:
+		return false
+	}
+}
+
+type storeContactsResponse struct {
+	JobID JobID `json:"job_id"`
+}
+
+// Create or update contacts, returning the id of the asynchronous import
+// job SendGrid creates to process them rather than waiting for it to
+// finish; poll its progress with ContactImportStatus or WaitForImport.
+func (c client) StoreContactsAsync(contacts []*Contact, lists []string, opts ...RequestOption) (JobID, error) {
+	entity := storeContactsRequest{
+		Contacts: contacts,
+		Lists:    lists,
+	}
+
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("PUT", urls.Join(c.base, "/marketing/contacts"), bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	_, data, err = c.Send(req, append([]RequestOption{withAutoIdempotencyKey()}, opts...)...)
+	if err != nil {
+		return "", err
+	}
+
+	res := &storeContactsResponse{}
+	err = json.Unmarshal(data, res)
+	if err != nil {
+		return "", err
+	}
+
+	return res.JobID, nil
+}
+
+// Fetch the status of a contacts import job
+func (c client) ContactImportStatus(id JobID) (*ImportStatus, error) {
+	req, err := http.NewRequest("GET", urls.Join(c.base, "/marketing/contacts/imports/"+string(id)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	_, data, err := c.Send(req)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &ImportStatus{}
+	err = json.Unmarshal(data, status)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
+
+// Poll a contacts import job until it reaches a terminal status, or ctx
+// is canceled.
+func (c client) WaitForImport(ctx context.Context, id JobID, poll time.Duration) (*ImportStatus, error) {
+	for {
+		status, err := c.ContactImportStatus(id)
+		if err != nil {
+			return nil, err
+		}
+		if status.isTerminal() {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
+
+// The outcome of one batch submitted by StreamContacts
+type StreamResult struct {
+	JobID JobID
+	Count int
+	Err   error
+}
+
+// Consume an unbounded stream of contacts, chunking it into PUTs of at
+// most batchSize contacts each (capped at SendGrid's documented limit of
+// 30,000) and submitting up to concurrency batches at once. The returned
+// channel carries one StreamResult per batch and is closed once in is
+// drained and every in-flight batch has been submitted, or once ctx is
+// canceled; after cancellation, remaining contacts are not submitted and
+// results not yet delivered are dropped.
+func (c client) StreamContacts(ctx context.Context, in <-chan *Contact, lists []string, batchSize, concurrency int) <-chan StreamResult {
+	return streamContacts(ctx, in, batchSize, concurrency, func(batch []*Contact) (JobID, error) {
+		return c.StoreContactsAsync(batch, lists)
+	})
+}
+
+// streamContacts implements the chunking and fan-out shared by every
+// StreamContacts implementation: it batches in into groups of at most
+// batchSize contacts (capped at maxContactBatchSize) and hands up to
+// concurrency batches at once to store, which submits one batch and
+// reports its outcome.
+func streamContacts(ctx context.Context, in <-chan *Contact, batchSize, concurrency int, store func(batch []*Contact) (JobID, error)) <-chan StreamResult {
+	if batchSize <= 0 || batchSize > maxContactBatchSize {
+		batchSize = maxContactBatchSize
+	}
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	batches := make(chan []*Contact)
+	results := make(chan StreamResult)
+
+	go func() {
+		defer close(batches)
+		batch := make([]*Contact, 0, batchSize)
+		flush := func() {
+			if len(batch) == 0 {
+				return
+			}
+			select {
+			case batches <- batch:
+			case <-ctx.Done():
+			}
+			batch = make([]*Contact, 0, batchSize)
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case contact, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, contact)
+				if len(batch) >= batchSize {
+					flush()
+				}
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				id, err := store(batch)
+				select {
+				case results <- StreamResult{JobID: id, Count: len(batch), Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,144 @@
+package sendgrid
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWaitForImportReturnsOnTerminalStatus(t *testing.T) {
+	var polls int
+	statuses := []string{"pending", "processing", "completed"}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := statuses[polls]
+		if polls < len(statuses)-1 {
+			polls++
+		}
+		json.NewEncoder(w).Encode(ImportStatus{Id: "job-1", Status: status})
+	}))
+	defer srv.Close()
+
+	c, err := New("apikey", Endpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	got, err := c.WaitForImport(context.Background(), "job-1", time.Millisecond)
+	if err != nil {
+		t.Fatalf("WaitForImport: %v", err)
+	}
+	if got.Status != "completed" {
+		t.Fatalf("expected terminal status %q, got %q", "completed", got.Status)
+	}
+	if polls != len(statuses)-1 {
+		t.Fatalf("expected %d polls before completion, got %d", len(statuses)-1, polls)
+	}
+}
+
+func TestWaitForImportStopsOnContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ImportStatus{Id: "job-1", Status: "processing"})
+	}))
+	defer srv.Close()
+
+	c, err := New("apikey", Endpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = c.WaitForImport(ctx, "job-1", time.Hour)
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestStreamContactsChunksIntoBatches(t *testing.T) {
+	var mu sync.Mutex
+	var batchSizes []int
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req storeContactsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		mu.Lock()
+		batchSizes = append(batchSizes, len(req.Contacts))
+		mu.Unlock()
+		json.NewEncoder(w).Encode(storeContactsResponse{JobID: "job-1"})
+	}))
+	defer srv.Close()
+
+	c, err := New("apikey", Endpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	in := make(chan *Contact)
+	go func() {
+		defer close(in)
+		for i := 0; i < 5; i++ {
+			in <- &Contact{Email: "a@example.com"}
+		}
+	}()
+
+	results := c.StreamContacts(context.Background(), in, nil, 2, 1)
+
+	var total, batches int
+	for r := range results {
+		if r.Err != nil {
+			t.Errorf("unexpected result error: %v", r.Err)
+		}
+		total += r.Count
+		batches++
+	}
+
+	if total != 5 {
+		t.Fatalf("expected 5 contacts streamed, got %d", total)
+	}
+	if batches != 3 {
+		t.Fatalf("expected 3 batches of at most 2 contacts each, got %d", batches)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, n := range batchSizes {
+		if n > 2 {
+			t.Fatalf("expected batch sizes capped at 2, saw batch of %d", n)
+		}
+	}
+}
+
+func TestStreamContactsStopsOnContextCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(storeContactsResponse{JobID: "job-1"})
+	}))
+	defer srv.Close()
+
+	c, err := New("apikey", Endpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan *Contact)
+	results := c.StreamContacts(ctx, in, nil, 2, 1)
+	cancel()
+
+	for range results {
+	}
+
+	select {
+	case in <- &Contact{}:
+		t.Fatal("expected no consumer to still be reading after cancel")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(in)
+}
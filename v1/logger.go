@@ -0,0 +1,58 @@
+package sendgrid
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// A Logger receives structured diagnostic events from the client. Each
+// method takes a message followed by alternating key/value pairs, the
+// same convention used by log/slog and most structured loggers.
+type Logger interface {
+	Debug(msg string, kv ...interface{})
+	Info(msg string, kv ...interface{})
+	Warn(msg string, kv ...interface{})
+	Error(msg string, kv ...interface{})
+}
+
+// noopLogger discards everything; it is the default when no Logger is
+// configured and Verbose is off.
+type noopLogger struct{}
+
+func (noopLogger) Debug(msg string, kv ...interface{}) {}
+func (noopLogger) Info(msg string, kv ...interface{})  {}
+func (noopLogger) Warn(msg string, kv ...interface{})  {}
+func (noopLogger) Error(msg string, kv ...interface{}) {}
+
+// stdoutLogger reproduces this package's historical fmt.Println-based
+// dump; it is selected automatically when Verbose(true) is set without an
+// explicit Logger.
+type stdoutLogger struct{}
+
+func (stdoutLogger) Debug(msg string, kv ...interface{}) { printKV(msg, kv...) }
+func (stdoutLogger) Info(msg string, kv ...interface{})  { printKV(msg, kv...) }
+func (stdoutLogger) Warn(msg string, kv ...interface{})  { printKV(msg, kv...) }
+func (stdoutLogger) Error(msg string, kv ...interface{}) { printKV(msg, kv...) }
+
+func printKV(msg string, kv ...interface{}) {
+	fmt.Print(msg)
+	for i := 0; i+1 < len(kv); i += 2 {
+		fmt.Printf(" %v=%v", kv[i], kv[i+1])
+	}
+	fmt.Println()
+}
+
+// SlogLogger adapts a *slog.Logger to the Logger interface.
+type SlogLogger struct {
+	*slog.Logger
+}
+
+// NewSlogLogger wraps l as a Logger
+func NewSlogLogger(l *slog.Logger) SlogLogger {
+	return SlogLogger{l}
+}
+
+func (s SlogLogger) Debug(msg string, kv ...interface{}) { s.Logger.Debug(msg, kv...) }
+func (s SlogLogger) Info(msg string, kv ...interface{})  { s.Logger.Info(msg, kv...) }
+func (s SlogLogger) Warn(msg string, kv ...interface{})  { s.Logger.Warn(msg, kv...) }
+func (s SlogLogger) Error(msg string, kv ...interface{}) { s.Logger.Error(msg, kv...) }
@@ -1,11 +1,11 @@
 package sendgrid
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/url"
+	"time"
 
-	"github.com/bww/go-util/v1/text"
 	"github.com/bww/go-util/v1/urls"
 )
 
@@ -13,7 +13,8 @@ type mock struct {
 	base            string
 	defaultSender   Address
 	overrideAddress string
-	verbose         bool
+	logger          Logger
+	templates       Template
 }
 
 func Mock(apikey string, opts ...Option) (Client, error) {
@@ -23,24 +24,68 @@ func Mock(apikey string, opts ...Option) (Client, error) {
 	for _, o := range opts {
 		conf = o(conf)
 	}
+	templates, err := MockTemplates(apikey, conf.PreviewWriter, opts...)
+	if err != nil {
+		return nil, err
+	}
 	return &mock{
 		base:            conf.Endpoint,
 		defaultSender:   conf.DefaultSender,
 		overrideAddress: conf.OverrideAddress,
-		verbose:         conf.Verbose,
+		logger:          effectiveLogger(conf),
+		templates:       templates,
 	}, nil
 }
 
-func (c mock) SendEmail(email *Email) error {
-	c.dump("POST", "/mail/send", prepareEmail(email, c.defaultSender, c.overrideAddress))
+// SendEmail dumps the request like every other Mock call, except when
+// email carries a TemplateId: SendGrid would render that server-side, so
+// here each personalization is rendered and previewed locally instead,
+// letting developers see the actual email a template produces offline.
+func (c mock) SendEmail(email *Email, opts ...RequestOption) error {
+	prepared := prepareEmail(email, c.defaultSender, c.overrideAddress)
+
+	if prepared.TemplateId != "" {
+		for _, p := range prepared.Personalizations {
+			data := make(map[string]interface{}, len(p.Substitutions))
+			for k, v := range p.Substitutions {
+				data[k] = v
+			}
+			if _, err := c.templates.RenderEmail(prepared.TemplateId, data, p.Recipients); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	c.dump("POST", "/mail/send", prepared)
 	return nil
 }
 
-func (c mock) StoreContacts(contacts []*Contact, lists []string) error {
+func (c mock) StoreContacts(contacts []*Contact, lists []string, opts ...RequestOption) error {
 	c.dump("PUT", "/marketing/contacts", storeContactsRequest{Contacts: contacts, Lists: lists})
 	return nil
 }
 
+func (c mock) StoreContactsAsync(contacts []*Contact, lists []string, opts ...RequestOption) (JobID, error) {
+	c.dump("PUT", "/marketing/contacts", storeContactsRequest{Contacts: contacts, Lists: lists})
+	return "mock-job", nil
+}
+
+func (c mock) ContactImportStatus(id JobID) (*ImportStatus, error) {
+	c.dump("GET", "/marketing/contacts/imports/"+string(id), nil)
+	return &ImportStatus{Id: id, Status: "completed"}, nil
+}
+
+func (c mock) WaitForImport(ctx context.Context, id JobID, poll time.Duration) (*ImportStatus, error) {
+	return c.ContactImportStatus(id)
+}
+
+func (c mock) StreamContacts(ctx context.Context, contacts <-chan *Contact, lists []string, batchSize, concurrency int) <-chan StreamResult {
+	return streamContacts(ctx, contacts, batchSize, concurrency, func(batch []*Contact) (JobID, error) {
+		return c.StoreContactsAsync(batch, lists)
+	})
+}
+
 func (c mock) FetchContact(id string) (*Contact, error) {
 	params := make(url.Values)
 	params.Set("ext_id", id)
@@ -62,18 +107,20 @@ func (c mock) FetchContactWithParams(params url.Values) (*Contact, error) {
 	return nil, ErrNotFound
 }
 
+func (c mock) SendSMS(msg *SMS) error {
+	c.dump("POST", "/Accounts/mock/Messages.json", msg)
+	return nil
+}
+
 func (c mock) dump(method, url string, entity interface{}) error {
-	var data []byte
-	if c.verbose && entity != nil {
-		var err error
-		data, err = json.MarshalIndent(entity, "", "  ")
-		if err != nil {
-			return err
-		}
+	if entity == nil {
+		c.logger.Info("mock: request", "method", method, "url", urls.Join(c.base, url))
+		return nil
 	}
-	fmt.Printf("sendgrid: %s %s\n", method, urls.Join(c.base, url))
-	if len(data) > 0 {
-		fmt.Println(text.Indent(string(data), "        > "))
+	data, err := json.MarshalIndent(entity, "", "  ")
+	if err != nil {
+		return err
 	}
+	c.logger.Info("mock: request", "method", method, "url", urls.Join(c.base, url), "body", string(data))
 	return nil
 }
@@ -0,0 +1,87 @@
+package sendgrid
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestMockStreamContactsChunksIntoBatches(t *testing.T) {
+	c, err := Mock("apikey")
+	if err != nil {
+		t.Fatalf("Mock: %v", err)
+	}
+
+	in := make(chan *Contact)
+	go func() {
+		defer close(in)
+		for i := 0; i < 5; i++ {
+			in <- &Contact{Email: "a@example.com"}
+		}
+	}()
+
+	results := c.StreamContacts(context.Background(), in, nil, 2, 1)
+
+	var total, batches int
+	for r := range results {
+		if r.Err != nil {
+			t.Errorf("unexpected result error: %v", r.Err)
+		}
+		total += r.Count
+		batches++
+	}
+
+	if total != 5 {
+		t.Fatalf("expected 5 contacts streamed, got %d", total)
+	}
+	if batches != 3 {
+		t.Fatalf("expected 3 batches of at most 2 contacts each, got %d", batches)
+	}
+}
+
+func TestMockStreamContactsConcurrency(t *testing.T) {
+	c, err := Mock("apikey")
+	if err != nil {
+		t.Fatalf("Mock: %v", err)
+	}
+
+	in := make(chan *Contact)
+	go func() {
+		defer close(in)
+		for i := 0; i < 20; i++ {
+			in <- &Contact{Email: "a@example.com"}
+		}
+	}()
+
+	results := c.StreamContacts(context.Background(), in, nil, 1, 4)
+
+	var mu sync.Mutex
+	seenJobs := make(map[JobID]int)
+	for r := range results {
+		mu.Lock()
+		seenJobs[r.JobID]++
+		mu.Unlock()
+	}
+
+	if len(seenJobs) != 1 {
+		t.Fatalf("expected Mock's single fixed job id for every batch, got %d distinct ids", len(seenJobs))
+	}
+	if seenJobs["mock-job"] != 20 {
+		t.Fatalf("expected 20 batches of 1 contact each, got %d", seenJobs["mock-job"])
+	}
+}
+
+func TestMockStreamContactsStopsOnContextCancel(t *testing.T) {
+	c, err := Mock("apikey")
+	if err != nil {
+		t.Fatalf("Mock: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan *Contact)
+	results := c.StreamContacts(ctx, in, nil, 2, 1)
+	cancel()
+
+	for range results {
+	}
+}
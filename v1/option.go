@@ -1,5 +1,11 @@
 package sendgrid
 
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
 type Option func(Config) Config
 
 type Config struct {
@@ -7,6 +13,12 @@ type Config struct {
 	OverrideAddress string
 	DefaultSender   Address
 	Verbose         bool
+	RetryMax        int
+	RetryBase       time.Duration
+	RetryCap        time.Duration
+	Logger          Logger
+	HTTPClient      *http.Client
+	PreviewWriter   io.Writer
 }
 
 func Endpoint(base string) Option {
@@ -36,3 +48,60 @@ func Verbose(on bool) Option {
 		return c
 	}
 }
+
+// WithRetry enables retrying idempotent requests (GET/PUT, and POSTs
+// carrying an Idempotency-Key) up to max times on 429 and 5xx responses.
+// Backoff grows exponentially from base, capped at cap, with full jitter
+// applied on top; a Retry-After header on the response takes precedence
+// when present.
+func WithRetry(max int, base, cap time.Duration) Option {
+	return func(c Config) Config {
+		c.RetryMax = max
+		c.RetryBase = base
+		c.RetryCap = cap
+		return c
+	}
+}
+
+// WithLogger directs diagnostic output to l. When no Logger is configured,
+// Verbose(true) falls back to a stdout adapter that reproduces this
+// package's historical behavior, and otherwise output is discarded.
+func WithLogger(l Logger) Option {
+	return func(c Config) Config {
+		c.Logger = l
+		return c
+	}
+}
+
+// WithHTTPClient supplies the *http.Client used for requests, letting
+// callers inject their own transport (tracing, mTLS, proxying, ...)
+// instead of the package's default.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c Config) Config {
+		c.HTTPClient = hc
+		return c
+	}
+}
+
+// WithPreviewWriter directs a Mock client's rendered templated emails to
+// w instead of a temp file, so tests can capture the preview output
+// SendEmail writes for a templated Email.
+func WithPreviewWriter(w io.Writer) Option {
+	return func(c Config) Config {
+		c.PreviewWriter = w
+		return c
+	}
+}
+
+// effectiveLogger resolves the Logger that a client should use given its
+// configuration: an explicit Logger always wins, otherwise Verbose(true)
+// gets a stdout adapter, and the default is a silent no-op.
+func effectiveLogger(c Config) Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	if c.Verbose {
+		return stdoutLogger{}
+	}
+	return noopLogger{}
+}
@@ -0,0 +1,79 @@
+package sendgrid
+
+import (
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// requestConfig carries the effective, per-call settings for a single
+// logical request; it is seeded from the client's configuration and then
+// refined by any RequestOptions passed to the call.
+type requestConfig struct {
+	idempotencyKey string
+	timeout        time.Duration
+	retryMax       int
+	retryBase      time.Duration
+	retryCap       time.Duration
+}
+
+// A RequestOption overrides the client's defaults for a single call,
+// without mutating the client itself.
+type RequestOption func(requestConfig) requestConfig
+
+// WithIdempotencyKey sets an explicit Idempotency-Key for this call instead
+// of the one generated automatically.
+func WithIdempotencyKey(key string) RequestOption {
+	return func(c requestConfig) requestConfig {
+		c.idempotencyKey = key
+		return c
+	}
+}
+
+// WithRequestTimeout overrides the client's configured timeout for this call.
+func WithRequestTimeout(d time.Duration) RequestOption {
+	return func(c requestConfig) requestConfig {
+		c.timeout = d
+		return c
+	}
+}
+
+// WithRequestRetry overrides the client's retry policy for this call; see
+// WithRetry for the meaning of the arguments.
+func WithRequestRetry(max int, base, cap time.Duration) RequestOption {
+	return func(c requestConfig) requestConfig {
+		c.retryMax = max
+		c.retryBase = base
+		c.retryCap = cap
+		return c
+	}
+}
+
+// isIdempotentMethod reports whether method is safe to retry on its own
+func isIdempotentMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodPut
+}
+
+// isRetryableStatus reports whether status is worth retrying
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// backoff computes how long to wait before the next attempt, honoring a
+// Retry-After header when the response provides one and otherwise using
+// exponential backoff with full jitter: sleep = rand(0, min(cap, base*2^n))
+func backoff(rsp *http.Response, attempt int, base, cap time.Duration) time.Duration {
+	if rsp != nil {
+		if v := rsp.Header.Get("Retry-After"); v != "" {
+			if secs, err := strconv.Atoi(v); err == nil {
+				return time.Duration(secs) * time.Second
+			}
+		}
+	}
+	d := base * time.Duration(int64(1)<<uint(attempt))
+	if d <= 0 || d > cap {
+		d = cap
+	}
+	return time.Duration(rand.Int63n(int64(d) + 1))
+}
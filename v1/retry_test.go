@@ -0,0 +1,77 @@
+package sendgrid
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoffRetryAfterTakesPrecedence(t *testing.T) {
+	rsp := &http.Response{Header: http.Header{"Retry-After": []string{"7"}}}
+	d := backoff(rsp, 5, time.Second, time.Minute)
+	if d != 7*time.Second {
+		t.Fatalf("expected Retry-After to win with 7s, got %v", d)
+	}
+}
+
+func TestBackoffIgnoresInvalidRetryAfter(t *testing.T) {
+	rsp := &http.Response{Header: http.Header{"Retry-After": []string{"not-a-number"}}}
+	d := backoff(rsp, 0, time.Second, time.Minute)
+	if d > time.Second {
+		t.Fatalf("expected fallback to exponential backoff bounded by base*2^0=1s, got %v", d)
+	}
+}
+
+func TestBackoffExponentialGrowthIsCapped(t *testing.T) {
+	base := 10 * time.Millisecond
+	cap := 100 * time.Millisecond
+
+	for attempt := 0; attempt < 10; attempt++ {
+		for i := 0; i < 50; i++ {
+			d := backoff(nil, attempt, base, cap)
+			if d < 0 || d > cap {
+				t.Fatalf("attempt %d: backoff %v out of bounds [0, %v]", attempt, d, cap)
+			}
+		}
+	}
+}
+
+func TestBackoffNilResponseUsesExponential(t *testing.T) {
+	base := time.Second
+	cap := time.Minute
+	for i := 0; i < 50; i++ {
+		d := backoff(nil, 1, base, cap)
+		if d > 2*base {
+			t.Fatalf("attempt 1: expected backoff bounded by base*2^1=%v, got %v", 2*base, d)
+		}
+	}
+}
+
+func TestIsIdempotentMethod(t *testing.T) {
+	cases := map[string]bool{
+		http.MethodGet:    true,
+		http.MethodPut:    true,
+		http.MethodPost:   false,
+		http.MethodDelete: false,
+	}
+	for method, want := range cases {
+		if got := isIdempotentMethod(method); got != want {
+			t.Errorf("isIdempotentMethod(%q) = %v, want %v", method, got, want)
+		}
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	cases := map[int]bool{
+		http.StatusOK:                  false,
+		http.StatusTooManyRequests:     true,
+		http.StatusInternalServerError: true,
+		http.StatusBadGateway:          true,
+		http.StatusBadRequest:          false,
+	}
+	for status, want := range cases {
+		if got := isRetryableStatus(status); got != want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
@@ -50,12 +50,21 @@ func NewAttachment(mtype, fname string, data []byte) *Attachment {
 	}
 }
 
+// Inline body content for an email that isn't driven by a SendGrid
+// dynamic template, e.g. one rendered locally by Template.RenderEmail
+type Content struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
 // A templated email
 type Email struct {
 	TemplateId       string            `json:"template_id"`
+	Subject          string            `json:"subject,omitempty"`
 	From             Address           `json:"from"`
 	ReplyTo          Address           `json:"reply_to"`
 	Personalizations []Personalization `json:"personalizations"`
+	Content          []Content         `json:"content,omitempty"`
 	Attachments      []*Attachment     `json:"attachments"`
 }
 
@@ -72,15 +81,25 @@ type Contact struct {
 	Fields    Fields   `json:"custom_fields,omitempty"`
 }
 
-// An error
+// An error, as SendGrid reports it in a response's JSON error payload.
+// Field and Help are populated by endpoints like mail send that report
+// one error per offending field; Indices is populated by the contacts
+// import endpoints that report one error per offending input row.
 type Error struct {
 	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+	Help    string `json:"help,omitempty"`
 	Indices []int  `json:"error_indices,omitempty"`
 }
 
 func (e Error) Error() string {
 	var s strings.Builder
 	s.WriteString(e.Message)
+	if e.Field != "" {
+		s.WriteString(" (field: ")
+		s.WriteString(e.Field)
+		s.WriteString(")")
+	}
 	if debug.VERBOSE && len(e.Indices) > 0 {
 		s.WriteString(" (input indices: ")
 		for i, e := range e.Indices {
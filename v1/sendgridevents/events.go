@@ -0,0 +1,135 @@
+// Package sendgridevents handles SendGrid's two inbound webhooks: the
+// Event Webhook, which reports delivery events for mail already sent, and
+// Inbound Parse, which delivers mail received on a configured domain. It
+// verifies the Event Webhook's signature and decodes each webhook's payload
+// into typed Go structs so callers don't have to; Inbound Parse deliveries
+// are not signature-checked.
+package sendgridevents
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// EventType discriminates the kind of event reported by the Event Webhook
+type EventType string
+
+const (
+	EventProcessed        EventType = "processed"
+	EventDropped          EventType = "dropped"
+	EventDelivered        EventType = "delivered"
+	EventDeferred         EventType = "deferred"
+	EventBounce           EventType = "bounce"
+	EventOpen             EventType = "open"
+	EventClick            EventType = "click"
+	EventSpamReport       EventType = "spamreport"
+	EventUnsubscribe      EventType = "unsubscribe"
+	EventGroupUnsubscribe EventType = "group_unsubscribe"
+	EventGroupResubscribe EventType = "group_resubscribe"
+)
+
+// Event holds the fields common to every event the webhook reports.
+// SendGrid mixes type-specific fields into the same flat JSON object, so
+// the typed events below embed Event and add only their own fields.
+type Event struct {
+	Email       string    `json:"email"`
+	Timestamp   int64     `json:"timestamp"`
+	Event       EventType `json:"event"`
+	SGEventId   string    `json:"sg_event_id"`
+	SGMessageId string    `json:"sg_message_id"`
+}
+
+// Time returns the event's timestamp as a time.Time
+func (e Event) Time() time.Time {
+	return time.Unix(e.Timestamp, 0)
+}
+
+type DeliveredEvent struct {
+	Event
+	Response string `json:"response"`
+}
+
+type DeferredEvent struct {
+	Event
+	Response string `json:"response"`
+	Attempt  string `json:"attempt"`
+}
+
+type BounceEvent struct {
+	Event
+	Reason string `json:"reason"`
+	Status string `json:"status"`
+	Type   string `json:"type"`
+}
+
+type OpenEvent struct {
+	Event
+	UserAgent string `json:"useragent"`
+	IP        string `json:"ip"`
+}
+
+type ClickEvent struct {
+	Event
+	URL       string `json:"url"`
+	UserAgent string `json:"useragent"`
+	IP        string `json:"ip"`
+}
+
+type SpamReportEvent struct {
+	Event
+}
+
+type UnsubscribeEvent struct {
+	Event
+}
+
+type GroupUnsubscribeEvent struct {
+	Event
+	ASMGroupId int `json:"asm_group_id"`
+}
+
+type GroupResubscribeEvent struct {
+	Event
+	ASMGroupId int `json:"asm_group_id"`
+}
+
+// A HandlerFunc processes one decoded event. evt holds a pointer to the
+// concrete type matching the event's "event" field (*DeliveredEvent,
+// *BounceEvent, *OpenEvent, ...), or *Event for event types without a
+// dedicated struct. Returning an error aborts dispatch of the remaining
+// events in the delivery and fails the webhook request.
+type HandlerFunc func(evt interface{}) error
+
+// decodeTypedEvent unmarshals raw into the concrete struct matching kind,
+// discriminating on the event's "event" field.
+func decodeTypedEvent(kind EventType, raw json.RawMessage) (interface{}, error) {
+	var evt interface{}
+	switch kind {
+	case EventDelivered:
+		evt = &DeliveredEvent{}
+	case EventDeferred:
+		evt = &DeferredEvent{}
+	case EventBounce:
+		evt = &BounceEvent{}
+	case EventOpen:
+		evt = &OpenEvent{}
+	case EventClick:
+		evt = &ClickEvent{}
+	case EventSpamReport:
+		evt = &SpamReportEvent{}
+	case EventUnsubscribe:
+		evt = &UnsubscribeEvent{}
+	case EventGroupUnsubscribe:
+		evt = &GroupUnsubscribeEvent{}
+	case EventGroupResubscribe:
+		evt = &GroupResubscribeEvent{}
+	default:
+		// EventProcessed, EventDropped, and any event type SendGrid adds in
+		// the future decode into the generic Event so dispatch still works.
+		evt = &Event{}
+	}
+	if err := json.Unmarshal(raw, evt); err != nil {
+		return nil, err
+	}
+	return evt, nil
+}
@@ -0,0 +1,92 @@
+package sendgridevents
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Handler is an http.Handler that verifies and decodes deliveries of
+// SendGrid's Event Webhook, then dispatches each event to the handler
+// funcs registered with On.
+type Handler struct {
+	// PublicKey verifies the Event Webhook signature; if nil, signature
+	// verification is skipped, which should only be used for local testing.
+	PublicKey *ecdsa.PublicKey
+
+	handlers map[EventType][]HandlerFunc
+	fallback []HandlerFunc
+}
+
+// NewHandler creates an event webhook handler that verifies deliveries
+// against pub
+func NewHandler(pub *ecdsa.PublicKey) *Handler {
+	return &Handler{
+		PublicKey: pub,
+		handlers:  make(map[EventType][]HandlerFunc),
+	}
+}
+
+// On registers fn to run for events of the given types, or for every
+// event if no types are given.
+func (h *Handler) On(fn HandlerFunc, types ...EventType) {
+	if len(types) == 0 {
+		h.fallback = append(h.fallback, fn)
+		return
+	}
+	for _, t := range types {
+		h.handlers[t] = append(h.handlers[t], fn)
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.PublicKey != nil {
+		ok := VerifySignature(h.PublicKey, r.Header.Get(SignatureHeader), r.Header.Get(TimestampHeader), body)
+		if !ok {
+			http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(body, &raws); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, raw := range raws {
+		var base Event
+		if err := json.Unmarshal(raw, &base); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		typed, err := decodeTypedEvent(base.Event, raw)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		for _, fn := range h.handlers[base.Event] {
+			if err := fn(typed); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		for _, fn := range h.fallback {
+			if err := fn(typed); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
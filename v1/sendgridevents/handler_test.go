@@ -0,0 +1,110 @@
+package sendgridevents
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func mustGenerateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return priv
+}
+
+func TestHandlerDispatchesTypedEvents(t *testing.T) {
+	h := NewHandler(nil)
+
+	var got *BounceEvent
+	h.On(func(evt interface{}) error {
+		b, ok := evt.(*BounceEvent)
+		if !ok {
+			t.Fatalf("expected *BounceEvent, got %T", evt)
+		}
+		got = b
+		return nil
+	}, EventBounce)
+
+	var fallbackCount int
+	h.On(func(evt interface{}) error {
+		fallbackCount++
+		return nil
+	})
+
+	body := `[{"event":"bounce","email":"a@example.com","reason":"mailbox full"}]`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got == nil {
+		t.Fatal("expected bounce handler to run")
+	}
+	if got.Reason != "mailbox full" {
+		t.Fatalf("expected reason %q, got %q", "mailbox full", got.Reason)
+	}
+	if got.Email != "a@example.com" {
+		t.Fatalf("expected email %q, got %q", "a@example.com", got.Email)
+	}
+	if fallbackCount != 1 {
+		t.Fatalf("expected fallback handler to run once, got %d", fallbackCount)
+	}
+}
+
+func TestHandlerAbortsOnHandlerError(t *testing.T) {
+	h := NewHandler(nil)
+
+	wantErr := "boom"
+	h.On(func(evt interface{}) error {
+		return errBoom(wantErr)
+	}, EventDelivered)
+
+	var secondRan bool
+	h.On(func(evt interface{}) error {
+		secondRan = true
+		return nil
+	})
+
+	body := `[{"event":"delivered"},{"event":"delivered"}]`
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 when a handler fails, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), wantErr) {
+		t.Fatalf("expected response body to contain %q, got %q", wantErr, rec.Body.String())
+	}
+	if secondRan {
+		t.Fatal("expected dispatch to stop after the first handler error")
+	}
+}
+
+type errBoom string
+
+func (e errBoom) Error() string { return string(e) }
+
+func TestHandlerRejectsInvalidSignature(t *testing.T) {
+	priv := mustGenerateKey(t)
+	h := NewHandler(&priv.PublicKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`[]`))
+	req.Header.Set(SignatureHeader, "bogus")
+	req.Header.Set(TimestampHeader, "1690000000")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 for an invalid signature, got %d", rec.Code)
+	}
+}
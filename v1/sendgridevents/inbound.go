@@ -0,0 +1,102 @@
+package sendgridevents
+
+import (
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// maxInboundMemory bounds how much of a multipart Inbound Parse request is
+// buffered in memory before attachments spill to temp files.
+const maxInboundMemory = 32 << 20 // 32MiB
+
+// InboundAttachment is one file attached to an inbound email
+type InboundAttachment struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// InboundEmail is a message delivered by SendGrid's Inbound Parse webhook
+type InboundEmail struct {
+	To          string
+	From        string
+	Subject     string
+	Text        string
+	HTML        string
+	Headers     string
+	Envelope    string
+	Charsets    string
+	SPF         string
+	Attachments []InboundAttachment
+}
+
+// ParseInbound decodes a multipart/form-data POST from SendGrid's Inbound
+// Parse webhook into an InboundEmail.
+func ParseInbound(r *http.Request) (*InboundEmail, error) {
+	if err := r.ParseMultipartForm(maxInboundMemory); err != nil {
+		return nil, err
+	}
+	form := r.MultipartForm
+
+	email := &InboundEmail{
+		To:       formValue(form, "to"),
+		From:     formValue(form, "from"),
+		Subject:  formValue(form, "subject"),
+		Text:     formValue(form, "text"),
+		HTML:     formValue(form, "html"),
+		Headers:  formValue(form, "headers"),
+		Envelope: formValue(form, "envelope"),
+		Charsets: formValue(form, "charsets"),
+		SPF:      formValue(form, "SPF"),
+	}
+
+	for _, fhs := range form.File {
+		for _, fh := range fhs {
+			f, err := fh.Open()
+			if err != nil {
+				return nil, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, err
+			}
+			email.Attachments = append(email.Attachments, InboundAttachment{
+				Filename:    fh.Filename,
+				ContentType: fh.Header.Get("Content-Type"),
+				Content:     data,
+			})
+		}
+	}
+
+	return email, nil
+}
+
+func formValue(form *multipart.Form, key string) string {
+	if form == nil || len(form.Value[key]) == 0 {
+		return ""
+	}
+	return form.Value[key][0]
+}
+
+// InboundHandler is an http.Handler that parses Inbound Parse deliveries
+// and passes each one to Handle.
+type InboundHandler struct {
+	Handle func(email *InboundEmail) error
+}
+
+func (h InboundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	email, err := ParseInbound(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.Handle != nil {
+		if err := h.Handle(email); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,35 @@
+package sendgridevents
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// Headers SendGrid attaches to each Event Webhook POST so the payload's
+// authenticity and freshness can be verified.
+const (
+	SignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	TimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+)
+
+// VerifySignature reports whether signature is a valid base64-encoded
+// ECDSA signature, made with the account's Event Webhook private key,
+// over timestamp concatenated with the raw request body.
+func VerifySignature(pub *ecdsa.PublicKey, signature, timestamp string, body []byte) bool {
+	if pub == nil || signature == "" || timestamp == "" {
+		return false
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	payload := make([]byte, 0, len(timestamp)+len(body))
+	payload = append(payload, timestamp...)
+	payload = append(payload, body...)
+	sum := sha256.Sum256(payload)
+
+	return ecdsa.VerifyASN1(pub, sum[:], sig)
+}
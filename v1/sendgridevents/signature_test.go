@@ -0,0 +1,64 @@
+package sendgridevents
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"testing"
+)
+
+func signPayload(t *testing.T, priv *ecdsa.PrivateKey, timestamp string, body []byte) string {
+	t.Helper()
+	payload := append([]byte(timestamp), body...)
+	sum := sha256.Sum256(payload)
+	sig, err := ecdsa.SignASN1(rand.Reader, priv, sum[:])
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(sig)
+}
+
+func TestVerifySignature(t *testing.T) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pub := &priv.PublicKey
+
+	body := []byte(`[{"event":"delivered"}]`)
+	timestamp := "1690000000"
+	sig := signPayload(t, priv, timestamp, body)
+
+	if !VerifySignature(pub, sig, timestamp, body) {
+		t.Fatal("expected valid signature to verify")
+	}
+
+	if VerifySignature(pub, sig, timestamp, []byte(`[{"event":"bounce"}]`)) {
+		t.Fatal("expected signature over different body to fail")
+	}
+	if VerifySignature(pub, sig, "1690000001", body) {
+		t.Fatal("expected signature with different timestamp to fail")
+	}
+	if VerifySignature(pub, "not-base64!!", timestamp, body) {
+		t.Fatal("expected malformed signature to fail")
+	}
+	if VerifySignature(pub, "", timestamp, body) {
+		t.Fatal("expected empty signature to fail")
+	}
+	if VerifySignature(pub, sig, "", body) {
+		t.Fatal("expected empty timestamp to fail")
+	}
+	if VerifySignature(nil, sig, timestamp, body) {
+		t.Fatal("expected nil public key to fail")
+	}
+
+	other, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	if VerifySignature(&other.PublicKey, sig, timestamp, body) {
+		t.Fatal("expected signature verified against the wrong key to fail")
+	}
+}
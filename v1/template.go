@@ -0,0 +1,273 @@
+package sendgrid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bww/go-util/v1/urls"
+)
+
+// A Template renders a named template against caller-supplied data into a
+// ready-to-send Email. Implementations may resolve the template against
+// SendGrid's Dynamic Templates API, a local registry, or both.
+type Template interface {
+	// Register compiles subject and html under id so future RenderEmail
+	// calls for id resolve locally instead of calling SendGrid.
+	Register(id, subject, html string) error
+	RenderEmail(templateID string, data interface{}, to []Address) (*Email, error)
+}
+
+type localTemplate struct {
+	subject *template.Template
+	html    *template.Template
+}
+
+// templates is the default Template: ids registered with Register render
+// locally via text/template; anything else is resolved against SendGrid's
+// Dynamic Templates API and rendered with a small handlebars-compatible
+// substitution engine, since SendGrid's dynamic templates use {{var}}
+// syntax rather than Go's.
+type templates struct {
+	client *http.Client
+	base   string
+	apikey string
+	logger Logger
+	local  map[string]localTemplate
+}
+
+// NewTemplates creates a Template that resolves against SendGrid's
+// Dynamic Templates API for any id not registered locally.
+func NewTemplates(apikey string, opts ...Option) (Template, error) {
+	conf := Config{
+		Endpoint: defaultEndpoint,
+	}
+	for _, o := range opts {
+		conf = o(conf)
+	}
+	hc := conf.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: time.Second * 30}
+	}
+	return &templates{
+		client: hc,
+		base:   conf.Endpoint,
+		apikey: apikey,
+		logger: effectiveLogger(conf),
+		local:  make(map[string]localTemplate),
+	}, nil
+}
+
+func (t *templates) Register(id, subject, html string) error {
+	st, err := template.New(id + ".subject").Parse(subject)
+	if err != nil {
+		return err
+	}
+	ht, err := template.New(id + ".html").Parse(html)
+	if err != nil {
+		return err
+	}
+	t.local[id] = localTemplate{subject: st, html: ht}
+	return nil
+}
+
+func (t *templates) RenderEmail(templateID string, data interface{}, to []Address) (*Email, error) {
+	subject, html, err := t.resolve(templateID, data)
+	if err != nil {
+		return nil, err
+	}
+	return renderedEmail(subject, html, to), nil
+}
+
+func (t *templates) resolve(id string, data interface{}) (subject, html string, err error) {
+	if lt, ok := t.local[id]; ok {
+		var sb, hb bytes.Buffer
+		if err := lt.subject.Execute(&sb, data); err != nil {
+			return "", "", err
+		}
+		if err := lt.html.Execute(&hb, data); err != nil {
+			return "", "", err
+		}
+		return sb.String(), hb.String(), nil
+	}
+
+	rawSubject, rawHTML, err := t.fetchDynamicTemplate(id)
+	if err != nil {
+		return "", "", err
+	}
+	return renderHandlebars(rawSubject, data), renderHandlebars(rawHTML, data), nil
+}
+
+// fetchDynamicTemplate fetches the active version of a SendGrid Dynamic
+// Template, returning its (unrendered) subject and HTML content.
+func (t *templates) fetchDynamicTemplate(id string) (subject, html string, err error) {
+	req, err := http.NewRequest("GET", urls.Join(t.base, "/templates/"+id), nil)
+	if err != nil {
+		return "", "", err
+	}
+	if t.apikey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", t.apikey))
+	}
+
+	rsp, err := t.client.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer rsp.Body.Close()
+
+	data, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	if rsp.StatusCode < 200 || rsp.StatusCode >= 300 {
+		return "", "", newAPIError(rsp, data, ErrNotFound)
+	}
+
+	res := &struct {
+		Versions []struct {
+			Active      int    `json:"active"`
+			Subject     string `json:"subject"`
+			HTMLContent string `json:"html_content"`
+		} `json:"versions"`
+	}{}
+	if err := json.Unmarshal(data, res); err != nil {
+		return "", "", err
+	}
+	for _, v := range res.Versions {
+		if v.Active == 1 {
+			return v.Subject, v.HTMLContent, nil
+		}
+	}
+
+	return "", "", ErrNotFound
+}
+
+func renderedEmail(subject, html string, to []Address) *Email {
+	recipients := make([]Address, len(to))
+	copy(recipients, to)
+	return &Email{
+		Subject: subject,
+		Personalizations: []Personalization{
+			{Recipients: recipients, Subject: subject},
+		},
+		Content: []Content{
+			{Type: "text/html", Value: html},
+		},
+	}
+}
+
+var handlebarsVar = regexp.MustCompile(`\{\{\s*([a-zA-Z0-9_.]+)\s*\}\}`)
+
+// renderHandlebars performs minimal handlebars-compatible {{var}}
+// substitution against data, which may be a map[string]interface{},
+// map[string]string, or a struct matched by field name. It's a small
+// in-tree stand-in for SendGrid's Handlebars dialect, not a full
+// implementation: helpers, partials and block expressions aren't supported.
+func renderHandlebars(tmpl string, data interface{}) string {
+	return handlebarsVar.ReplaceAllStringFunc(tmpl, func(match string) string {
+		key := strings.TrimSpace(match[2 : len(match)-2])
+		if v, ok := lookupField(data, key); ok {
+			return fmt.Sprintf("%v", v)
+		}
+		return match
+	})
+}
+
+func lookupField(data interface{}, key string) (interface{}, bool) {
+	switch d := data.(type) {
+	case map[string]interface{}:
+		v, ok := d[key]
+		return v, ok
+	case map[string]string:
+		v, ok := d[key]
+		return v, ok
+	}
+	rv := reflect.ValueOf(data)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, false
+	}
+	fv := rv.FieldByName(key)
+	if !fv.IsValid() || !fv.CanInterface() {
+		return nil, false
+	}
+	return fv.Interface(), true
+}
+
+// mockTemplates renders exactly like templates, but also writes each
+// render to w (or, if w is nil, a file under os.TempDir()) so developers
+// can preview a transactional email without a live SendGrid account.
+type mockTemplates struct {
+	templates
+	w io.Writer
+}
+
+// MockTemplates creates a Template that renders like NewTemplates and
+// previews its output via w. Locally registered ids render offline; other
+// ids are still fetched from SendGrid's Dynamic Templates API. If w is nil,
+// each render is instead written to a file in os.TempDir() named after the
+// template id and the recipient.
+func MockTemplates(apikey string, w io.Writer, opts ...Option) (Template, error) {
+	t, err := NewTemplates(apikey, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &mockTemplates{templates: *t.(*templates), w: w}, nil
+}
+
+func (t *mockTemplates) RenderEmail(templateID string, data interface{}, to []Address) (*Email, error) {
+	email, err := t.templates.RenderEmail(templateID, data, to)
+	if err != nil {
+		return nil, err
+	}
+	t.preview(templateID, email)
+	return email, nil
+}
+
+// preview writes one rendered email, keyed by its recipient so that
+// multiple personalizations of the same template (e.g. a batch send to
+// several recipients) each get their own preview instead of overwriting
+// one another.
+func (t *mockTemplates) preview(templateID string, email *Email) {
+	var html string
+	for _, c := range email.Content {
+		if c.Type == "text/html" {
+			html = c.Value
+		}
+	}
+	recipient := previewRecipient(email)
+	out := fmt.Sprintf("To: %s\nSubject: %s\n\n%s", recipient, email.Subject, html)
+
+	if t.w != nil {
+		io.WriteString(t.w, out)
+		return
+	}
+
+	fname := filepath.Join(os.TempDir(), fmt.Sprintf("sendgrid-preview-%s-%s.html", templateID, recipient))
+	if err := ioutil.WriteFile(fname, []byte(out), 0644); err != nil {
+		t.logger.Error("sendgrid: could not write template preview", "template", templateID, "file", fname, "error", err)
+		return
+	}
+	t.logger.Info("sendgrid: wrote template preview", "template", templateID, "file", fname)
+}
+
+// previewRecipient returns the address a rendered email's preview should
+// be keyed by, falling back to "unknown" if it carries no recipients.
+func previewRecipient(email *Email) string {
+	if len(email.Personalizations) == 0 || len(email.Personalizations[0].Recipients) == 0 {
+		return "unknown"
+	}
+	return email.Personalizations[0].Recipients[0].Email
+}
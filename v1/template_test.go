@@ -0,0 +1,211 @@
+package sendgrid
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRenderHandlebarsMapStringInterface(t *testing.T) {
+	data := map[string]interface{}{"name": "Ada", "count": 3}
+	got := renderHandlebars("Hi {{name}}, you have {{count}} messages", data)
+	want := "Hi Ada, you have 3 messages"
+	if got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRenderHandlebarsMapStringString(t *testing.T) {
+	data := map[string]string{"name": "Grace"}
+	got := renderHandlebars("Hello {{ name }}", data)
+	if got != "Hello Grace" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestRenderHandlebarsStructFields(t *testing.T) {
+	type vars struct {
+		Name string
+		age  int
+	}
+	got := renderHandlebars("Hi {{Name}}, age {{age}}", vars{Name: "Lin", age: 30})
+	want := "Hi Lin, age {{age}}"
+	if got != want {
+		t.Fatalf("expected unexported field to be left unsubstituted, got %q", got)
+	}
+}
+
+func TestRenderHandlebarsUnknownKeyLeftAsIs(t *testing.T) {
+	got := renderHandlebars("Hi {{missing}}", map[string]interface{}{})
+	if got != "Hi {{missing}}" {
+		t.Fatalf("expected unmatched variable to pass through unchanged, got %q", got)
+	}
+}
+
+func TestLookupFieldUnexportedFieldDoesNotPanic(t *testing.T) {
+	type s struct {
+		unexported string
+	}
+	if _, ok := lookupField(s{unexported: "x"}, "unexported"); ok {
+		t.Fatal("expected unexported field lookup to fail, not succeed")
+	}
+}
+
+func TestLookupFieldPointerToStruct(t *testing.T) {
+	type s struct {
+		Name string
+	}
+	v, ok := lookupField(&s{Name: "Rex"}, "Name")
+	if !ok || v != "Rex" {
+		t.Fatalf("expected to resolve Name via pointer, got %v, %v", v, ok)
+	}
+}
+
+func TestTemplatesRegisterAndRenderEmailLocal(t *testing.T) {
+	tpl, err := NewTemplates("")
+	if err != nil {
+		t.Fatalf("NewTemplates: %v", err)
+	}
+	if err := tpl.Register("welcome", "Hi {{.Name}}", "<p>Hi {{.Name}}</p>"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	email, err := tpl.RenderEmail("welcome", struct{ Name string }{Name: "Theo"}, []Address{{Email: "theo@example.com"}})
+	if err != nil {
+		t.Fatalf("RenderEmail: %v", err)
+	}
+	if email.Subject != "Hi Theo" {
+		t.Fatalf("expected rendered subject %q, got %q", "Hi Theo", email.Subject)
+	}
+	if len(email.Content) != 1 || !strings.Contains(email.Content[0].Value, "Hi Theo") {
+		t.Fatalf("expected rendered html to contain %q, got %+v", "Hi Theo", email.Content)
+	}
+}
+
+func TestMockTemplatesPreviewWritesToWriter(t *testing.T) {
+	var buf bytes.Buffer
+	tpl, err := MockTemplates("", &buf)
+	if err != nil {
+		t.Fatalf("MockTemplates: %v", err)
+	}
+	if err := tpl.Register("welcome", "Hi {{.Name}}", "<p>Hi {{.Name}}</p>"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	_, err = tpl.RenderEmail("welcome", struct{ Name string }{Name: "Mina"}, []Address{{Email: "mina@example.com"}})
+	if err != nil {
+		t.Fatalf("RenderEmail: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "Hi Mina") {
+		t.Fatalf("expected preview to be written to the writer, got %q", buf.String())
+	}
+}
+
+func TestMockTemplatesPreviewUsesOneFilePerRecipient(t *testing.T) {
+	tpl, err := MockTemplates("", nil)
+	if err != nil {
+		t.Fatalf("MockTemplates: %v", err)
+	}
+	if err := tpl.Register("welcome", "Hi {{.Name}}", "<p>Hi {{.Name}}</p>"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	if _, err := tpl.RenderEmail("welcome", struct{ Name string }{Name: "Alice"}, []Address{{Email: "alice@example.com"}}); err != nil {
+		t.Fatalf("RenderEmail: %v", err)
+	}
+	aliceFile := filepath.Join(os.TempDir(), "sendgrid-preview-welcome-alice@example.com.html")
+	defer os.Remove(aliceFile)
+	aliceData, err := os.ReadFile(aliceFile)
+	if err != nil {
+		t.Fatalf("expected a preview file for alice, got: %v", err)
+	}
+	if !strings.Contains(string(aliceData), "Hi Alice") {
+		t.Fatalf("expected alice's preview to contain %q, got %q", "Hi Alice", aliceData)
+	}
+
+	if _, err := tpl.RenderEmail("welcome", struct{ Name string }{Name: "Bob"}, []Address{{Email: "bob@example.com"}}); err != nil {
+		t.Fatalf("RenderEmail: %v", err)
+	}
+	bobFile := filepath.Join(os.TempDir(), "sendgrid-preview-welcome-bob@example.com.html")
+	defer os.Remove(bobFile)
+	bobData, err := os.ReadFile(bobFile)
+	if err != nil {
+		t.Fatalf("expected a preview file for bob, got: %v", err)
+	}
+	if !strings.Contains(string(bobData), "Hi Bob") {
+		t.Fatalf("expected bob's preview to contain %q, got %q", "Hi Bob", bobData)
+	}
+
+	// alice's preview must still exist and be unaffected by bob's render
+	aliceData, err = os.ReadFile(aliceFile)
+	if err != nil {
+		t.Fatalf("expected alice's preview to survive bob's render, got: %v", err)
+	}
+	if !strings.Contains(string(aliceData), "Hi Alice") {
+		t.Fatalf("expected alice's preview to still contain %q, got %q", "Hi Alice", aliceData)
+	}
+}
+
+func TestMockSendEmailWithTemplateIdDelegatesToTemplates(t *testing.T) {
+	var buf bytes.Buffer
+	c, err := Mock("apikey", WithPreviewWriter(&buf))
+	if err != nil {
+		t.Fatalf("Mock: %v", err)
+	}
+
+	m := c.(*mock)
+	if err := m.templates.Register("welcome", "Hi {{.Name}}", "<p>Hi {{.Name}}</p>"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	email := &Email{
+		TemplateId: "welcome",
+		Personalizations: []Personalization{
+			{
+				Recipients:    []Address{{Email: "pat@example.com"}},
+				Substitutions: Substitutions{"Name": "Pat"},
+			},
+		},
+	}
+
+	if err := c.SendEmail(email); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Hi Pat") {
+		t.Fatalf("expected SendEmail to render the template via Mock's Template, got %q", buf.String())
+	}
+}
+
+func TestMockSendEmailPreviewsEveryPersonalization(t *testing.T) {
+	var buf bytes.Buffer
+	c, err := Mock("apikey", WithPreviewWriter(&buf))
+	if err != nil {
+		t.Fatalf("Mock: %v", err)
+	}
+
+	m := c.(*mock)
+	if err := m.templates.Register("welcome", "Hi {{.Name}}", "<p>Hi {{.Name}}</p>"); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	email := &Email{
+		TemplateId: "welcome",
+		Personalizations: []Personalization{
+			{Recipients: []Address{{Email: "pat@example.com"}}, Substitutions: Substitutions{"Name": "Pat"}},
+			{Recipients: []Address{{Email: "robin@example.com"}}, Substitutions: Substitutions{"Name": "Robin"}},
+		},
+	}
+
+	if err := c.SendEmail(email); err != nil {
+		t.Fatalf("SendEmail: %v", err)
+	}
+	if !strings.Contains(buf.String(), "Hi Pat") {
+		t.Fatalf("expected preview output to contain Pat's rendered email, got %q", buf.String())
+	}
+	if !strings.Contains(buf.String(), "Hi Robin") {
+		t.Fatalf("expected preview output to contain Robin's rendered email too, got %q", buf.String())
+	}
+}
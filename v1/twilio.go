@@ -0,0 +1,116 @@
+package sendgrid
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bww/go-util/v1/debug"
+)
+
+const defaultTwilioEndpoint = "https://api.twilio.com/2010-04-01"
+
+// An SMS message sent via Twilio
+type SMS struct {
+	From      string
+	To        string
+	Body      string
+	MediaURLs []string
+}
+
+// A client that can send SMS messages via Twilio
+type SMSClient interface {
+	SendSMS(msg *SMS) error
+}
+
+// Twilio account credentials
+type TwilioConfig struct {
+	AccountSID string
+	AuthToken  string
+	From       string
+}
+
+type twilio struct {
+	client     *http.Client
+	base       string
+	accountSID string
+	authToken  string
+	from       string
+	logger     Logger
+}
+
+// Create a Twilio SMS client
+func NewTwilio(conf TwilioConfig, opts ...Option) (SMSClient, error) {
+	c := Config{
+		Endpoint: defaultTwilioEndpoint,
+		Verbose:  debug.VERBOSE || debug.DEBUG,
+	}
+	for _, o := range opts {
+		c = o(c)
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: time.Second * 30}
+	}
+	return &twilio{
+		client:     hc,
+		base:       c.Endpoint,
+		accountSID: conf.AccountSID,
+		authToken:  conf.AuthToken,
+		from:       conf.From,
+		logger:     effectiveLogger(c),
+	}, nil
+}
+
+// Send an SMS message
+func (c twilio) SendSMS(msg *SMS) error {
+	from := msg.From
+	if from == "" {
+		from = c.from
+	}
+
+	data := url.Values{}
+	data.Set("To", msg.To)
+	data.Set("From", from)
+	data.Set("Body", msg.Body)
+	for _, m := range msg.MediaURLs {
+		data.Add("MediaUrl", m)
+	}
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", c.base, c.accountSID)
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(c.accountSID, c.authToken)
+
+	// the auth token travels in the Basic Authorization header, which we
+	// never log here, so there's nothing further to mask
+	c.logger.Debug("twilio: request", "method", req.Method, "url", req.URL.String(), "body", data.Encode())
+
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode >= 200 && rsp.StatusCode < 300 {
+		return nil
+	}
+
+	switch rsp.StatusCode {
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusInternalServerError:
+		return ErrServiceError
+	default:
+		return fmt.Errorf("Unexpected status code: %v", rsp.Status)
+	}
+}
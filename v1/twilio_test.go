@@ -0,0 +1,95 @@
+package sendgrid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestTwilioSendSMSEncodesFormAndBasicAuth(t *testing.T) {
+	var gotUser, gotPass string
+	var gotOK bool
+	var gotBody url.Values
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUser, gotPass, gotOK = r.BasicAuth()
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("ParseForm: %v", err)
+		}
+		gotBody = r.PostForm
+		if got := r.URL.Path; got != "/Accounts/AC123/Messages.json" {
+			t.Errorf("expected path %q, got %q", "/Accounts/AC123/Messages.json", got)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c, err := NewTwilio(TwilioConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15550000000"}, Endpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewTwilio: %v", err)
+	}
+
+	err = c.SendSMS(&SMS{To: "+15551234567", Body: "hello", MediaURLs: []string{"https://example.com/a.png"}})
+	if err != nil {
+		t.Fatalf("SendSMS: %v", err)
+	}
+
+	if !gotOK {
+		t.Fatal("expected the request to carry HTTP Basic auth")
+	}
+	if gotUser != "AC123" || gotPass != "secret" {
+		t.Fatalf("expected basic auth AC123:secret, got %s:%s", gotUser, gotPass)
+	}
+	if got := gotBody.Get("To"); got != "+15551234567" {
+		t.Errorf("expected To %q, got %q", "+15551234567", got)
+	}
+	if got := gotBody.Get("From"); got != "+15550000000" {
+		t.Errorf("expected From to fall back to the configured number, got %q", got)
+	}
+	if got := gotBody.Get("Body"); got != "hello" {
+		t.Errorf("expected Body %q, got %q", "hello", got)
+	}
+	if got := gotBody["MediaUrl"]; len(got) != 1 || got[0] != "https://example.com/a.png" {
+		t.Errorf("expected MediaUrl %v, got %v", []string{"https://example.com/a.png"}, got)
+	}
+}
+
+func TestTwilioSendSMSFromOverridesConfig(t *testing.T) {
+	var gotFrom string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		gotFrom = r.PostForm.Get("From")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	c, err := NewTwilio(TwilioConfig{AccountSID: "AC123", AuthToken: "secret", From: "+15550000000"}, Endpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewTwilio: %v", err)
+	}
+
+	if err := c.SendSMS(&SMS{From: "+19998887777", To: "+15551234567", Body: "hi"}); err != nil {
+		t.Fatalf("SendSMS: %v", err)
+	}
+	if gotFrom != "+19998887777" {
+		t.Fatalf("expected per-message From to override the configured default, got %q", gotFrom)
+	}
+}
+
+func TestTwilioSendSMSMapsErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	c, err := NewTwilio(TwilioConfig{AccountSID: "AC123", AuthToken: "bad"}, Endpoint(srv.URL))
+	if err != nil {
+		t.Fatalf("NewTwilio: %v", err)
+	}
+
+	err = c.SendSMS(&SMS{To: "+15551234567", Body: "hi"})
+	if err != ErrUnauthorized {
+		t.Fatalf("expected ErrUnauthorized, got %v", err)
+	}
+}